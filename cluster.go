@@ -0,0 +1,79 @@
+package main
+
+import "fmt"
+
+// assignClusters sets each table's Cluster field, used by PrintDOT and
+// PrintGraphML to group related tables visually. mode is one of:
+//
+//	"prefix"  - the naming heuristic, grp(t.Name), unconditionally
+//	"louvain" - graph-based community detection over FK relationships
+//	"none"    - no clustering; Cluster is left empty
+//	""        - not explicitly set: keep whatever Cluster a table already
+//	            carries (e.g. persisted in a cached -json crawl by an
+//	            earlier -cluster=louvain run) and only fall back to the
+//	            prefix heuristic for tables with no Cluster yet, such as
+//	            a fresh crawl
+func assignClusters(tables []Table, constraints map[string][]Constraint, mode string) {
+	switch mode {
+	case "none":
+		for i := range tables {
+			tables[i].Cluster = ""
+		}
+	case "louvain":
+		assignLouvainClusters(tables, constraints)
+	case "prefix":
+		for i := range tables {
+			tables[i].Cluster = grp(tables[i].Name)
+		}
+	default:
+		for i := range tables {
+			if tables[i].Cluster == "" {
+				tables[i].Cluster = grp(tables[i].Name)
+			}
+		}
+	}
+}
+
+// assignLouvainClusters clusters tables by running Louvain community
+// detection over a graph where nodes are tables and edge weights are the
+// number of FK relationships between two tables, plus a small bonus when
+// both share the same Owner.
+func assignLouvainClusters(tables []Table, constraints map[string][]Constraint) {
+	idx := make(map[string]int, len(tables))
+	for i, t := range tables {
+		idx[t.Owner+"."+t.Name] = i
+	}
+
+	const sameOwnerBonus = 0.5
+	edges := make(map[[2]int]float64)
+	addWeight := func(i, j int, w float64) {
+		if i > j {
+			i, j = j, i
+		}
+		edges[[2]int{i, j}] += w
+	}
+	for key, cs := range constraints {
+		i, ok := idx[key]
+		if !ok {
+			continue
+		}
+		for _, c := range cs {
+			if c.Type != "R" || c.RemoteTable == "" {
+				continue
+			}
+			j, ok := idx[c.RemoteOwner+"."+c.RemoteTable]
+			if !ok || j == i {
+				continue
+			}
+			addWeight(i, j, 1)
+			if tables[i].Owner == tables[j].Owner {
+				addWeight(i, j, sameOwnerBonus)
+			}
+		}
+	}
+
+	comm := louvainCommunities(len(tables), edges)
+	for i := range tables {
+		tables[i].Cluster = fmt.Sprintf("louvain%d", comm[i])
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAssignClusters(t *testing.T) {
+	cases := []struct {
+		name   string
+		mode   string
+		tables []Table
+		want   []string
+	}{
+		{
+			name: "none clears any existing Cluster",
+			mode: "none",
+			tables: []Table{
+				{Name: "USER_ORDERS", Cluster: "louvain0"},
+				{Name: "USERS"},
+			},
+			want: []string{"", ""},
+		},
+		{
+			name: "prefix overwrites any existing Cluster",
+			mode: "prefix",
+			tables: []Table{
+				{Name: "USER_ORDERS", Cluster: "louvain0"},
+				{Name: "USERS"},
+			},
+			want: []string{"ORDERS", "USERS"},
+		},
+		{
+			name: "unset mode falls back to prefix for tables with no Cluster yet",
+			mode: "",
+			tables: []Table{
+				{Name: "USER_ORDERS"},
+				{Name: "USERS"},
+			},
+			want: []string{"ORDERS", "USERS"},
+		},
+		{
+			name: "unset mode keeps a Cluster persisted by an earlier crawl",
+			mode: "",
+			tables: []Table{
+				{Name: "USER_ORDERS", Cluster: "louvain0"},
+				{Name: "USERS", Cluster: "louvain1"},
+			},
+			want: []string{"louvain0", "louvain1"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assignClusters(c.tables, nil, c.mode)
+			for i, table := range c.tables {
+				if table.Cluster != c.want[i] {
+					t.Errorf("tables[%d].Cluster = %q, want %q", i, table.Cluster, c.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAssignClustersLouvainGroupsFKLinkedTables(t *testing.T) {
+	tables := []Table{
+		{Owner: "APP", Name: "USERS"},
+		{Owner: "APP", Name: "USER_ORDERS"},
+		{Owner: "APP", Name: "PRODUCTS"},
+	}
+	constraints := map[string][]Constraint{
+		"APP.USER_ORDERS": {
+			{
+				Owner: "APP", Name: "ORDERS_FK", Type: "R", Table: "USER_ORDERS",
+				TableConstraint: TableConstraint{Columns: []string{"USER_ID"}, RemoteOwner: "APP", RemoteTable: "USERS", RemoteName: "USERS_PK"},
+			},
+		},
+	}
+
+	assignClusters(tables, constraints, "louvain")
+
+	for i, table := range tables {
+		if table.Cluster == "" || !strings.HasPrefix(table.Cluster, "louvain") {
+			t.Errorf("tables[%d].Cluster = %q, want a non-empty %q-prefixed label", i, table.Cluster, "louvain")
+		}
+	}
+	if tables[0].Cluster != tables[1].Cluster {
+		t.Errorf("FK-linked tables USERS (%q) and USER_ORDERS (%q) ended up in different clusters", tables[0].Cluster, tables[1].Cluster)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GraphML namespaces and the yEd "nodegraphics" key id, which yEd expects
+// to be exactly "d0" on the node holding the <y:ShapeNode>.
+const (
+	graphmlXMLNS  = "http://graphml.graphdrawing.org/xmlns"
+	graphmlYXMLNS = "http://www.yworks.com/xml/graphml"
+
+	keyNodeGraphics = "d0"
+	keyNodeOwner    = "d1"
+	keyNodeName     = "d2"
+	keyNodeComment  = "d3"
+	keyEdgeFKName   = "d4"
+	keyEdgeColumns  = "d5"
+	keyEdgeRemote   = "d6"
+)
+
+type gmlDoc struct {
+	XMLName xml.Name `xml:"graphml"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	XMLNSY  string   `xml:"xmlns:y,attr"`
+	Keys    []gmlKey `xml:"key"`
+	Graph   gmlGraph `xml:"graph"`
+}
+
+type gmlKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type gmlGraph struct {
+	ID          string     `xml:"id,attr"`
+	EdgeDefault string     `xml:"edgedefault,attr"`
+	Nodes       []*gmlNode `xml:"node"`
+	Edges       []*gmlEdge `xml:"edge,omitempty"`
+}
+
+type gmlNode struct {
+	ID    string    `xml:"id,attr"`
+	Data  []gmlData `xml:"data"`
+	Graph *gmlGraph `xml:"graph,omitempty"`
+}
+
+type gmlData struct {
+	Key       string        `xml:"key,attr"`
+	Value     string        `xml:",chardata"`
+	ShapeNode *gmlShapeNode `xml:"y:ShapeNode"`
+}
+
+type gmlShapeNode struct {
+	Label string `xml:"y:NodeLabel"`
+}
+
+type gmlEdge struct {
+	ID         string    `xml:"id,attr"`
+	Source     string    `xml:"source,attr"`
+	Target     string    `xml:"target,attr"`
+	SourcePort string    `xml:"sourceport,attr,omitempty"`
+	TargetPort string    `xml:"targetport,attr,omitempty"`
+	Data       []gmlData `xml:"data"`
+}
+
+// PrintGraphML writes tables as a real, yEd/Gephi-loadable GraphML
+// document: one <node> per table carrying a yEd <y:ShapeNode> with the
+// same tabular label PrintNodeDOT renders, one <edge> per foreign key
+// with sourceport/targetport set to the matching column, and a nested
+// <graph> group per grp() cluster.
+func PrintGraphML(w io.Writer, tables []Table) error {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	if _, err := io.WriteString(bw, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(bw)
+	enc.Indent("", "  ")
+	if err := enc.Encode(buildGraphML(tables)); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(bw, "\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func buildGraphML(tables []Table) gmlDoc {
+	doc := gmlDoc{
+		XMLNS:  graphmlXMLNS,
+		XMLNSY: graphmlYXMLNS,
+		Keys: []gmlKey{
+			{ID: keyNodeGraphics, For: "node", AttrName: "nodegraphics", AttrType: "string"},
+			{ID: keyNodeOwner, For: "node", AttrName: "owner", AttrType: "string"},
+			{ID: keyNodeName, For: "node", AttrName: "name", AttrType: "string"},
+			{ID: keyNodeComment, For: "node", AttrName: "comment", AttrType: "string"},
+			{ID: keyEdgeFKName, For: "edge", AttrName: "fk_name", AttrType: "string"},
+			{ID: keyEdgeColumns, For: "edge", AttrName: "columns", AttrType: "string"},
+			{ID: keyEdgeRemote, For: "edge", AttrName: "remote_columns", AttrType: "string"},
+		},
+		Graph: gmlGraph{ID: "G", EdgeDefault: "directed"},
+	}
+
+	var groupOrder []string
+	groups := make(map[string]*gmlGraph)
+	for _, t := range tables {
+		g := t.Cluster
+		if g == "" {
+			doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode(t))
+			continue
+		}
+		gg, ok := groups[g]
+		if !ok {
+			gg = &gmlGraph{ID: "cluster_" + g, EdgeDefault: "directed"}
+			groups[g] = gg
+			groupOrder = append(groupOrder, g)
+		}
+		gg.Nodes = append(gg.Nodes, graphMLNode(t))
+	}
+	for _, g := range groupOrder {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, &gmlNode{
+			ID:    "cluster_" + g,
+			Data:  []gmlData{{Key: keyNodeName, Value: g}},
+			Graph: groups[g],
+		})
+	}
+
+	var edgeID int
+	for _, t := range tables {
+		for _, c := range t.Constraints {
+			if c.RemoteTable == "" {
+				continue
+			}
+			var col string
+			if len(c.Columns) != 0 {
+				col = c.Columns[0]
+			}
+			edgeID++
+			doc.Graph.Edges = append(doc.Graph.Edges, &gmlEdge{
+				ID:         fmt.Sprintf("e%d", edgeID),
+				Source:     name(t.Owner, t.Name),
+				Target:     name(c.RemoteOwner, c.RemoteTable),
+				SourcePort: col,
+				TargetPort: col,
+				Data: []gmlData{
+					{Key: keyEdgeFKName, Value: c.RemoteName},
+					{Key: keyEdgeColumns, Value: strings.Join(c.Columns, ",")},
+					{Key: keyEdgeRemote, Value: col},
+				},
+			})
+		}
+	}
+
+	return doc
+}
+
+func graphMLNode(t Table) *gmlNode {
+	return &gmlNode{
+		ID: name(t.Owner, t.Name),
+		Data: []gmlData{
+			{Key: keyNodeOwner, Value: t.Owner},
+			{Key: keyNodeName, Value: t.Name},
+			{Key: keyNodeComment, Value: t.Comment},
+			{Key: keyNodeGraphics, ShapeNode: &gmlShapeNode{Label: tableHTMLLabel(t)}},
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func testTables() []Table {
+	return []Table{
+		{
+			Owner:   "APP",
+			Name:    "USERS",
+			Comment: "application users",
+			Cluster: "USERS",
+			Columns: []Column{
+				{Name: "ID", Type: "NUMBER", Unique: true},
+				{Name: "NAME", Type: "VARCHAR2(100)"},
+			},
+		},
+		{
+			Owner:   "APP",
+			Name:    "USER_ORDERS",
+			Comment: "orders placed by a user",
+			Cluster: "ORDERS",
+			Columns: []Column{
+				{Name: "ID", Type: "NUMBER", Unique: true},
+				{Name: "USER_ID", Type: "NUMBER"},
+			},
+			Constraints: []TableConstraint{
+				{Columns: []string{"USER_ID"}, RemoteOwner: "APP", RemoteTable: "USERS", RemoteName: "USERS_PK"},
+			},
+		},
+	}
+}
+
+func TestPrintGraphMLGolden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PrintGraphML(&buf, testTables()); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc gmlDoc
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("PrintGraphML output does not round-trip through xml.Unmarshal: %v", err)
+	}
+	if doc.XMLNS != graphmlXMLNS {
+		t.Errorf("xmlns = %q, want %q", doc.XMLNS, graphmlXMLNS)
+	}
+	if len(doc.Graph.Edges) != 1 {
+		t.Fatalf("len(Graph.Edges) = %d, want 1", len(doc.Graph.Edges))
+	}
+	if got, want := doc.Graph.Edges[0].Target, name("APP", "USERS"); got != want {
+		t.Errorf("edge target = %q, want %q", got, want)
+	}
+
+	golden := filepath.Join("testdata", "graphml.golden.xml")
+	if *updateGolden {
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("PrintGraphML output differs from %s; rerun with -update", golden)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("schema").Parse(htmlTemplateSrc))
+
+// PrintHTML writes tables as a single, self-contained HTML file: the
+// same Table/Column JSON written to -json is embedded directly in the
+// page, and a small vanilla-JS viewer renders it offline, with a fuzzy
+// search box, a BFS "focus on neighborhood" mode over Table.Constraints,
+// a toggle for Unique columns, and a details pane for Comment text.
+func PrintHTML(w io.Writer, tables []Table) error {
+	payload, err := json.Marshal(tables)
+	if err != nil {
+		return err
+	}
+	return htmlTemplate.Execute(w, struct {
+		TablesJSON template.JS
+	}{TablesJSON: template.JS(payload)})
+}
+
+const htmlTemplateSrc = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>schema-graph</title>
+<style>
+  body { margin: 0; font-family: sans-serif; display: flex; height: 100vh; }
+  #toolbar { padding: 0.5em; border-bottom: 1px solid #ccc; display: flex; gap: 1em; align-items: center; }
+  #main { flex: 1; display: flex; flex-direction: column; min-width: 0; }
+  #cards { flex: 1; overflow: auto; padding: 0.5em; display: flex; flex-wrap: wrap; align-content: flex-start; gap: 0.5em; }
+  .card { border: 1px solid #999; border-radius: 4px; min-width: 220px; max-width: 320px; cursor: pointer; }
+  .card.hidden { display: none; }
+  .card.match { border-color: #2a6; box-shadow: 0 0 0 2px #2a6 inset; }
+  .card h3 { margin: 0; padding: 0.3em 0.5em; background: #eee; font-size: 0.95em; }
+  .card table { border-collapse: collapse; width: 100%; font-size: 0.85em; }
+  .card td { padding: 1px 4px; border-top: 1px solid #eee; }
+  .card td.col-unique { background: #ffe; }
+  #details { width: 320px; border-left: 1px solid #ccc; padding: 0.5em; overflow: auto; }
+  mark { background: #ff0; }
+</style>
+</head>
+<body>
+<div id="main">
+  <div id="toolbar">
+    <input id="search" type="search" placeholder="search tables/columns&hellip;">
+    <label>focus hops <input id="hops" type="number" min="0" value="2" style="width:3em"></label>
+    <button id="clearFocus">clear focus</button>
+    <label><input id="uniqueOnly" type="checkbox"> unique columns only</label>
+  </div>
+  <div id="cards"></div>
+</div>
+<div id="details">Click a table for details.</div>
+<script id="tables-json" type="application/json">{{.TablesJSON}}</script>
+<script>
+(function() {
+  "use strict";
+  var tables = JSON.parse(document.getElementById("tables-json").textContent);
+
+  function tableID(t) { return t.Owner + "." + t.Name; }
+
+  var byID = {};
+  tables.forEach(function(t) { byID[tableID(t)] = t; });
+
+  var adjacency = {};
+  tables.forEach(function(t) {
+    adjacency[tableID(t)] = adjacency[tableID(t)] || [];
+    (t.Constraints || []).forEach(function(c) {
+      if (!c.RemoteTable) return;
+      var remoteID = c.RemoteOwner + "." + c.RemoteTable;
+      adjacency[tableID(t)].push(remoteID);
+      adjacency[remoteID] = adjacency[remoteID] || [];
+      adjacency[remoteID].push(tableID(t));
+    });
+  });
+
+  function neighborhood(startID, hops) {
+    var seen = {};
+    seen[startID] = true;
+    var frontier = [startID];
+    for (var h = 0; h < hops && frontier.length; h++) {
+      var next = [];
+      frontier.forEach(function(id) {
+        (adjacency[id] || []).forEach(function(n) {
+          if (!seen[n]) { seen[n] = true; next.push(n); }
+        });
+      });
+      frontier = next;
+    }
+    return seen;
+  }
+
+  // fuzzyMatch reports whether needle's characters appear, in order, as a
+  // subsequence of haystack (case-insensitive).
+  function fuzzyMatch(haystack, needle) {
+    if (!needle) return true;
+    haystack = haystack.toLowerCase();
+    needle = needle.toLowerCase();
+    var hi = 0;
+    for (var ni = 0; ni < needle.length; ni++) {
+      hi = haystack.indexOf(needle[ni], hi);
+      if (hi === -1) return false;
+      hi++;
+    }
+    return true;
+  }
+
+  function tableMatches(t, needle) {
+    if (fuzzyMatch(tableID(t), needle)) return true;
+    return (t.Columns || []).some(function(c) { return fuzzyMatch(c.Name, needle); });
+  }
+
+  function renderDetails(t) {
+    var details = document.getElementById("details");
+    details.innerHTML = "";
+
+    var h2 = document.createElement("h2");
+    h2.textContent = t.Owner + "." + t.Name;
+    details.appendChild(h2);
+
+    if (t.Comment) {
+      var p = document.createElement("p");
+      p.textContent = t.Comment;
+      details.appendChild(p);
+    }
+
+    var tbl = document.createElement("table");
+    (t.Columns || []).forEach(function(c) {
+      var tr = document.createElement("tr");
+      var tdName = document.createElement("td");
+      var b = document.createElement("b");
+      b.textContent = c.Name;
+      tdName.appendChild(b);
+      var tdType = document.createElement("td");
+      tdType.textContent = c.Type;
+      tr.appendChild(tdName);
+      tr.appendChild(tdType);
+      tbl.appendChild(tr);
+
+      if (c.Comment) {
+        var crow = document.createElement("tr");
+        var ctd = document.createElement("td");
+        ctd.colSpan = 2;
+        var i = document.createElement("i");
+        i.textContent = c.Comment;
+        ctd.appendChild(i);
+        crow.appendChild(ctd);
+        tbl.appendChild(crow);
+      }
+    });
+    details.appendChild(tbl);
+  }
+
+  var focusID = null;
+
+  function render() {
+    var needle = document.getElementById("search").value;
+    var uniqueOnly = document.getElementById("uniqueOnly").checked;
+    var hops = parseInt(document.getElementById("hops").value, 10) || 0;
+    var visible = focusID ? neighborhood(focusID, hops) : null;
+
+    var cards = document.getElementById("cards");
+    cards.innerHTML = "";
+    tables.forEach(function(t) {
+      var id = tableID(t);
+      if (visible && !visible[id]) return;
+
+      var matches = tableMatches(t, needle);
+
+      var card = document.createElement("div");
+      card.className = "card" + (needle && matches ? " match" : "");
+      card.addEventListener("click", function() {
+        focusID = id;
+        renderDetails(t);
+        render();
+      });
+
+      var h3 = document.createElement("h3");
+      h3.textContent = t.Owner + "." + t.Name;
+      card.appendChild(h3);
+
+      var tbl = document.createElement("table");
+      (t.Columns || []).forEach(function(c) {
+        if (uniqueOnly && !c.Unique) return;
+        var tr = document.createElement("tr");
+        var tdName = document.createElement("td");
+        tdName.textContent = c.Name;
+        if (c.Unique) tdName.className = "col-unique";
+        var tdType = document.createElement("td");
+        tdType.textContent = c.Type;
+        tr.appendChild(tdName);
+        tr.appendChild(tdType);
+        tbl.appendChild(tr);
+      });
+      card.appendChild(tbl);
+
+      if (needle && !matches) card.classList.add("hidden");
+      cards.appendChild(card);
+    });
+  }
+
+  document.getElementById("search").addEventListener("input", render);
+  document.getElementById("hops").addEventListener("input", render);
+  document.getElementById("uniqueOnly").addEventListener("change", render);
+  document.getElementById("clearFocus").addEventListener("click", function() {
+    focusID = null;
+    render();
+  });
+
+  render();
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PrintHTML(&buf, testTables()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"<!DOCTYPE html>", "USER_ORDERS", "USERS_PK", `id="search"`, `id="hops"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+}
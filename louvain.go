@@ -0,0 +1,163 @@
+package main
+
+// louvainGraph is a weighted undirected graph over integer node ids
+// 0..n-1. Aggregation rounds fold several original nodes into one,
+// tracked via self, the node's own internal ("self-loop") weight.
+type louvainGraph struct {
+	n    int
+	adj  []map[int]float64
+	self []float64
+}
+
+func newLouvainGraph(n int) *louvainGraph {
+	adj := make([]map[int]float64, n)
+	for i := range adj {
+		adj[i] = make(map[int]float64)
+	}
+	return &louvainGraph{n: n, adj: adj, self: make([]float64, n)}
+}
+
+func (g *louvainGraph) addEdge(i, j int, w float64) {
+	if i == j {
+		g.self[i] += 2 * w
+		return
+	}
+	g.adj[i][j] += w
+	g.adj[j][i] += w
+}
+
+// degree returns k_i, the sum of edge weights incident to node i
+// (self-loops count twice, as usual for modularity).
+func (g *louvainGraph) degree(i int) float64 {
+	d := g.self[i]
+	for _, w := range g.adj[i] {
+		d += w
+	}
+	return d
+}
+
+// louvainCommunities runs the Louvain modularity-maximization algorithm
+// on the graph described by edges (a symmetric node-pair -> weight map
+// over nodes 0..n-1) and returns, for each original node, the id of the
+// community it ends up in after all aggregation levels. It is
+// deterministic: ties are always broken by the lower community id, so
+// re-running it on the same graph yields the same result.
+func louvainCommunities(n int, edges map[[2]int]float64) []int {
+	g := newLouvainGraph(n)
+	for k, w := range edges {
+		g.addEdge(k[0], k[1], w)
+	}
+
+	nodeOf := make([]int, n) // original node -> current-level node id
+	for i := range nodeOf {
+		nodeOf[i] = i
+	}
+
+	for {
+		comm, moved := louvainLocalMoving(g)
+		if !moved {
+			break
+		}
+		relabel, k := relabelCommunities(comm)
+		if k == g.n {
+			break // every node kept its own community: no further gain
+		}
+		for i := range nodeOf {
+			nodeOf[i] = relabel[comm[nodeOf[i]]]
+		}
+		g = aggregateGraph(g, comm, relabel, k)
+	}
+	return nodeOf
+}
+
+// louvainLocalMoving runs one level of greedy local-moving modularity
+// optimization: repeatedly move each node into whichever neighboring
+// community (possibly its own) maximizes the modularity gain
+// ΔQ = [(Σ_in + 2·k_i,in)/2m − ((Σ_tot + k_i)/2m)²] − [Σ_in/2m − (Σ_tot/2m)² − (k_i/2m)²],
+// until no node moves.
+func louvainLocalMoving(g *louvainGraph) ([]int, bool) {
+	n := g.n
+	comm := make([]int, n)
+	degree := make([]float64, n)
+	sigmaTot := make([]float64, n)
+	var m2 float64
+	for i := 0; i < n; i++ {
+		comm[i] = i
+		degree[i] = g.degree(i)
+		sigmaTot[i] = degree[i]
+		m2 += degree[i]
+	}
+	if m2 == 0 {
+		return comm, false
+	}
+
+	var improvedAny bool
+	for {
+		var moved bool
+		for i := 0; i < n; i++ {
+			ci := comm[i]
+			sigmaTot[ci] -= degree[i]
+
+			neighWeight := make(map[int]float64, len(g.adj[i]))
+			for j, w := range g.adj[i] {
+				neighWeight[comm[j]] += w
+			}
+
+			// ΔQ for moving i into community c, relative to leaving i isolated,
+			// simplifies (dropping terms constant across candidates) to
+			// k_i,in(c)/m - Σ_tot(c)·k_i/(2m²); we compare the m-scaled form.
+			bestComm, bestGain := ci, 0.0
+			for c, kIn := range neighWeight {
+				gain := kIn - sigmaTot[c]*degree[i]/m2
+				if gain > bestGain || (gain == bestGain && c < bestComm) {
+					bestGain, bestComm = gain, c
+				}
+			}
+			sigmaTot[bestComm] += degree[i]
+			comm[i] = bestComm
+			if bestComm != ci {
+				moved, improvedAny = true, true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+	return comm, improvedAny
+}
+
+// relabelCommunities maps the (possibly sparse) community ids in comm to
+// a contiguous range 0..k-1, in increasing order of first appearance.
+func relabelCommunities(comm []int) (map[int]int, int) {
+	relabel := make(map[int]int, len(comm))
+	for _, c := range comm {
+		if _, ok := relabel[c]; !ok {
+			relabel[c] = len(relabel)
+		}
+	}
+	return relabel, len(relabel)
+}
+
+// aggregateGraph contracts g's nodes into k super-nodes per relabel(comm),
+// folding intra-community edges into self-loops and summing
+// inter-community edges, ready for the next Louvain level.
+func aggregateGraph(g *louvainGraph, comm []int, relabel map[int]int, k int) *louvainGraph {
+	ng := newLouvainGraph(k)
+	for i := 0; i < g.n; i++ {
+		ci := relabel[comm[i]]
+		ng.self[ci] += g.self[i]
+		for j, w := range g.adj[i] {
+			if j <= i {
+				continue
+			}
+			cj := relabel[comm[j]]
+			if ci == cj {
+				ng.self[ci] += 2 * w
+			} else {
+				ng.adj[ci][cj] += w
+				ng.adj[cj][ci] += w
+			}
+		}
+	}
+	return ng
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestLouvainCommunitiesTwoCliques(t *testing.T) {
+	// Two disjoint triangles (0,1,2) and (3,4,5), with a single weak
+	// bridge edge between them: Louvain should put each triangle in its
+	// own community.
+	edges := map[[2]int]float64{
+		{0, 1}: 1, {1, 2}: 1, {0, 2}: 1,
+		{3, 4}: 1, {4, 5}: 1, {3, 5}: 1,
+		{2, 3}: 0.01,
+	}
+
+	comm := louvainCommunities(6, edges)
+
+	for _, pair := range [][2]int{{0, 1}, {1, 2}, {3, 4}, {4, 5}} {
+		if comm[pair[0]] != comm[pair[1]] {
+			t.Errorf("nodes %d and %d ended up in different communities (%v)", pair[0], pair[1], comm)
+		}
+	}
+	if comm[0] == comm[3] {
+		t.Errorf("the two triangles were merged into one community: %v", comm)
+	}
+}
+
+func TestLouvainCommunitiesDeterministic(t *testing.T) {
+	edges := map[[2]int]float64{
+		{0, 1}: 2, {1, 2}: 2, {2, 0}: 1,
+		{2, 3}: 1, {3, 4}: 2, {4, 5}: 2, {5, 3}: 1,
+	}
+
+	first := louvainCommunities(6, edges)
+	for i := 0; i < 5; i++ {
+		got := louvainCommunities(6, edges)
+		for n := range got {
+			if got[n] != first[n] {
+				t.Fatalf("run %d: node %d community = %d, want %d (non-deterministic)", i, n, got[n], first[n])
+			}
+		}
+	}
+}
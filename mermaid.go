@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/go-wordwrap"
+)
+
+var mermaidTypeSanitizer = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// PrintMermaid writes tables as a Mermaid erDiagram: one entity block per
+// table with PK/FK column markers (Column.Unique and the columns of a
+// TableConstraint, respectively), and one relationship line per foreign
+// key.
+func PrintMermaid(w io.Writer, tables []Table) error {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	bw.WriteString("erDiagram\n")
+	for _, t := range tables {
+		fkCols := make(map[string]struct{})
+		for _, c := range t.Constraints {
+			for _, col := range c.Columns {
+				fkCols[col] = struct{}{}
+			}
+		}
+
+		fmt.Fprintf(bw, "    %s {\n", name(t.Owner, t.Name))
+		for _, col := range t.Columns {
+			var keys []string
+			if col.Unique {
+				keys = append(keys, "PK")
+			}
+			if _, ok := fkCols[col.Name]; ok {
+				keys = append(keys, "FK")
+			}
+			fmt.Fprintf(bw, "        %s %s", mermaidType(col.Type), col.Name)
+			if len(keys) != 0 {
+				fmt.Fprintf(bw, " %s", strings.Join(keys, ","))
+			}
+			if col.Comment != "" {
+				fmt.Fprintf(bw, " %q", oneLine(wordwrap.WrapString(col.Comment, 60)))
+			}
+			bw.WriteString("\n")
+		}
+		bw.WriteString("    }\n")
+	}
+
+	for _, t := range tables {
+		for _, c := range t.Constraints {
+			if c.RemoteTable == "" {
+				continue
+			}
+			fmt.Fprintf(bw, "    %s ||--o{ %s : %q\n",
+				name(t.Owner, t.Name), name(c.RemoteOwner, c.RemoteTable), c.RemoteName)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// mermaidType sanitizes a SQL column type (which may contain spaces,
+// parentheses or commas, e.g. "VARCHAR2(100)") into the bare identifier
+// Mermaid's erDiagram attribute syntax requires.
+func mermaidType(t string) string {
+	return strings.Trim(mermaidTypeSanitizer.ReplaceAllString(t, "_"), "_")
+}
+
+// oneLine collapses a wordwrap.WrapString result back to a single line so
+// it can be embedded in a quoted Mermaid label.
+func oneLine(s string) string {
+	return strings.Join(strings.Split(s, "\n"), " ")
+}
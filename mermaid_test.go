@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PrintMermaid(&buf, testTables()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"erDiagram",
+		"APP__USERS {",
+		"APP__USER_ORDERS {",
+		"PK",
+		"FK",
+		`APP__USER_ORDERS ||--o{ APP__USERS : "USERS_PK"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mitchellh/go-wordwrap"
+)
+
+// PrintPlantUML writes tables as a PlantUML entity diagram: one entity
+// block per table, columns marked "*" when Column.Unique, and one ..>
+// relationship per foreign key labeled with TableConstraint.RemoteName.
+func PrintPlantUML(w io.Writer, tables []Table) error {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	bw.WriteString("@startuml\n")
+	for _, t := range tables {
+		fmt.Fprintf(bw, "entity %q {\n", name(t.Owner, t.Name))
+		writePlantUMLComment(bw, t.Comment)
+		for _, col := range t.Columns {
+			mark := "  "
+			if col.Unique {
+				mark = "* "
+			}
+			fmt.Fprintf(bw, "  %s%s : %s\n", mark, col.Name, col.Type)
+			writePlantUMLComment(bw, col.Comment)
+		}
+		bw.WriteString("}\n")
+	}
+
+	for _, t := range tables {
+		for _, c := range t.Constraints {
+			if c.RemoteTable == "" {
+				continue
+			}
+			fmt.Fprintf(bw, "%q ..> %q : %s\n",
+				name(t.Owner, t.Name), name(c.RemoteOwner, c.RemoteTable), c.RemoteName)
+		}
+	}
+
+	bw.WriteString("@enduml\n")
+	return bw.Flush()
+}
+
+// writePlantUMLComment emits comment as PlantUML "'" line comments,
+// word-wrapped at 60 columns; it writes nothing for an empty comment.
+func writePlantUMLComment(bw *bufio.Writer, comment string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(wordwrap.WrapString(comment, 60), "\n") {
+		fmt.Fprintf(bw, "  ' %s\n", line)
+	}
+}
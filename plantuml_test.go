@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintPlantUML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PrintPlantUML(&buf, testTables()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"@startuml",
+		`entity "APP__USERS" {`,
+		`entity "APP__USER_ORDERS" {`,
+		"* ID : NUMBER",
+		`"APP__USER_ORDERS" ..> "APP__USERS" : USERS_PK`,
+		"@enduml",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+}
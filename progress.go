@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// progress accumulates scan counts during a crawl for periodic reporting
+// via -progress. All methods are safe for concurrent use; a nil *progress
+// is a valid no-op (so callers don't need to special-case -progress=false).
+type progress struct {
+	tables      int64
+	constraints int64
+	start       time.Time
+}
+
+type progressContextKey struct{}
+
+// withProgress attaches p to ctx so SchemaReader implementations can
+// report scan counts without changing their interface signature.
+func withProgress(ctx context.Context, p *progress) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, p)
+}
+
+// progressFromContext returns the *progress attached by withProgress, or
+// nil if none was attached (the -progress flag is off).
+func progressFromContext(ctx context.Context) *progress {
+	p, _ := ctx.Value(progressContextKey{}).(*progress)
+	return p
+}
+
+func (p *progress) addTables(n int64) {
+	if p == nil {
+		return
+	}
+	atomic.AddInt64(&p.tables, n)
+}
+
+func (p *progress) addConstraints(n int64) {
+	if p == nil {
+		return
+	}
+	atomic.AddInt64(&p.constraints, n)
+}
+
+// report starts a goroutine that prints a progress line to stderr every
+// interval, until ctx is done or the returned stop func is called.
+func (p *progress) report(ctx context.Context, interval time.Duration) (stop func()) {
+	if p == nil {
+		return func() {}
+	}
+	p.start = time.Now()
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-t.C:
+				p.print()
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func (p *progress) print() {
+	fmt.Fprintf(os.Stderr, "progress: tables=%d constraints=%d elapsed=%s\n",
+		atomic.LoadInt64(&p.tables), atomic.LoadInt64(&p.constraints),
+		time.Since(p.start).Round(time.Second))
+}
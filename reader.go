@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// Filter narrows a schema crawl to a set of owners/schemas. An empty
+// Owners selects everything the connected user can see.
+type Filter struct {
+	Owners []string
+}
+
+// SchemaReader crawls a database's data dictionary into the Table/Constraint
+// model shared by every output writer (PrintDOT, PrintGML, ...).
+// Implementations are registered in readerFactories, keyed by the URL
+// scheme used on -connect.
+type SchemaReader interface {
+	ReadTables(ctx context.Context, filter Filter) ([]Table, error)
+	ReadConstraints(ctx context.Context, filter Filter) (map[string][]Constraint, error)
+}
+
+// driverNames maps a -connect URL scheme to the database/sql driver name
+// to open the connection with.
+var driverNames = map[string]string{
+	"":         "godror",
+	"oracle":   "godror",
+	"postgres": "postgres",
+	"mysql":    "mysql",
+}
+
+// readerFactories maps a -connect URL scheme to a SchemaReader constructor.
+var readerFactories = map[string]func(*sql.DB) SchemaReader{
+	"":         newOracleReader,
+	"oracle":   newOracleReader,
+	"postgres": newPostgresReader,
+	"mysql":    newMySQLReader,
+}
+
+// openReader opens connect (optionally prefixed with "scheme://") and
+// returns the SchemaReader and *sql.DB for it. A connect string with no
+// "scheme://" prefix (the traditional godror DSN form, e.g. "user/pw@tns")
+// is treated as "oracle" for backward compatibility.
+func openReader(connect string) (SchemaReader, *sql.DB, error) {
+	scheme, dsn := splitScheme(connect)
+	driver, ok := driverNames[scheme]
+	if !ok {
+		return nil, nil, errors.Errorf("unknown scheme %q in -connect", scheme)
+	}
+	db, err := sql.Open(driver, dsnFor(scheme, dsn))
+	if err != nil {
+		return nil, nil, err
+	}
+	return readerFactories[scheme](db), db, nil
+}
+
+// dsnFor adapts dsn (as split out by splitScheme, i.e. with the
+// "scheme://" prefix already removed) to the form each driver expects.
+// lib/pq only recognizes its URL connection form when the dsn still
+// starts with "postgres://"; without it, it silently falls back to
+// parsing as libpq keyword=value conninfo and drops the host/user/
+// password entirely, so the prefix is restored for that scheme. The
+// oracle and mysql drivers expect their native (unprefixed) DSN forms,
+// so dsn passes through unchanged for every other scheme.
+func dsnFor(scheme, dsn string) string {
+	if scheme == "postgres" {
+		return "postgres://" + dsn
+	}
+	return dsn
+}
+
+// splitScheme splits a "scheme://rest" connect string into scheme and the
+// remaining DSN. Strings without "://" have no scheme and are returned
+// unchanged as dsn. This intentionally does a plain substring split
+// instead of url.Parse: driver-native DSNs that follow the "scheme://"
+// prefix (e.g. a go-sql-driver/mysql "mysql://user:pw@tcp(host:3306)/db")
+// are not themselves valid URLs, and url.Parse rejects them outright.
+func splitScheme(connect string) (scheme, dsn string) {
+	i := strings.Index(connect, "://")
+	if i < 0 {
+		return "", connect
+	}
+	return connect[:i], connect[i+len("://"):]
+}
+
+// getTables crawls reader (or reads the cached jsonFile if reader is nil)
+// and returns the tables with their per-column Unique flag, Constraints
+// and Cluster (see assignClusters) filled in. On a fresh crawl, the
+// cluster assignment is computed before the JSON cache is written, so it
+// is persisted and re-renders from that cache stay stable.
+//
+// If ctx is canceled mid-crawl (e.g. SIGINT, see main), ReadTables/
+// ReadConstraints return whatever they had already assembled alongside
+// ctx.Err(); getTables logs that and carries on with the partial result
+// instead of failing outright, so the caller still gets a partial JSON
+// dump.
+func getTables(ctx context.Context, reader SchemaReader, jsonFile string, filter Filter, clusterMode string) ([]Table, error) {
+	var constraints map[string][]Constraint
+	var tables []Table
+	if reader != nil {
+		var grp errgroup.Group
+		grp.Go(func() error {
+			var err error
+			constraints, err = reader.ReadConstraints(ctx, filter)
+			return err
+		})
+
+		grp.Go(func() error {
+			var err error
+			tables, err = reader.ReadTables(ctx, filter)
+			return err
+		})
+		if err := grp.Wait(); err != nil {
+			if ctx.Err() == nil {
+				return tables, err
+			}
+			log.Printf("crawl interrupted (%v); continuing with partial result (%d tables)", err, len(tables))
+		}
+
+		assignClusters(tables, constraints, clusterMode)
+
+		if jsonFile != "" {
+			fh, err := os.Create(jsonFile)
+			if err != nil {
+				return tables, err
+			}
+			defer fh.Close()
+			enc := json.NewEncoder(fh)
+			if err := enc.Encode(constraints); err != nil {
+				return tables, err
+			}
+			if err := enc.Encode(tables); err != nil {
+				return tables, err
+			}
+			if err := fh.Close(); err != nil {
+				return tables, err
+			}
+		}
+	} else {
+		fh, err := os.Open(jsonFile)
+		if err != nil {
+			return tables, err
+		}
+		defer fh.Close()
+		dec := json.NewDecoder(fh)
+		if err := dec.Decode(&constraints); err != nil {
+			return tables, err
+		}
+		if err := dec.Decode(&tables); err != nil {
+			return tables, err
+		}
+		assignClusters(tables, constraints, clusterMode)
+	}
+
+	for i, t := range tables {
+		uCols := make(map[string]struct{}, len(t.Columns))
+		for _, c := range constraints[t.Owner+"."+t.Name] {
+			if c.Type == "R" {
+				t.Constraints = append(t.Constraints, c.TableConstraint)
+			} else if c.Type == "P" || c.Type == "U" {
+				for _, nm := range c.Columns {
+					uCols[nm] = struct{}{}
+				}
+			}
+		}
+		for i, c := range t.Columns {
+			_, ok := uCols[c.Name]
+			t.Columns[i].Unique = ok
+		}
+		tables[i] = t
+	}
+
+	sort.Sort(sort.Reverse(byRankSize(tables)))
+	sort.Stable(byNameGroup(tables))
+
+	return tables, nil
+}
+
+// scanTableRows groups consecutive rows of (owner, table, column name,
+// column type, table comment, column comment) into Tables. Rows must be
+// ordered by owner, table, column so each table's columns are contiguous.
+func scanTableRows(rows *sql.Rows) ([]Table, error) {
+	var tables []Table
+	var tp Table
+	for rows.Next() {
+		var c Column
+		var ta Table
+		if err := rows.Scan(&ta.Owner, &ta.Name, &c.Name, &c.Type, &ta.Comment, &c.Comment); err != nil {
+			return tables, err
+		}
+		ta.Comment = strings.TrimSpace(ta.Comment)
+		c.Comment = strings.TrimSpace(c.Comment)
+		ta.Columns = []Column{c}
+		if tp.Name == "" {
+			tp = ta
+			continue
+		}
+		if !(tp.Owner == ta.Owner && tp.Name == ta.Name) {
+			tables = append(tables, tp)
+			tp = ta
+			continue
+		}
+		tp.Columns = append(tp.Columns, c)
+	}
+	if tp.Name != "" {
+		tables = append(tables, tp)
+	}
+	return tables, rows.Err()
+}
+
+// scanConstraintRows groups consecutive rows of (owner, constraint name,
+// constraint type, table, column name, remote owner, remote table, remote
+// constraint name) into Constraints, keyed by "owner.table". Constraint
+// types are normalized to the Oracle-style single letters ("R", "P", "U")
+// used by getTables, so every SchemaReader can share this scanner.
+func scanConstraintRows(rows *sql.Rows) (map[string][]Constraint, error) {
+	constraints := make(map[string][]Constraint)
+	var cp Constraint
+	for rows.Next() {
+		var colName string
+		var ca Constraint
+		if err := rows.Scan(
+			&ca.Owner, &ca.Name, &ca.Type, &ca.Table, &colName,
+			&ca.RemoteOwner, &ca.RemoteTable, &ca.RemoteName,
+		); err != nil {
+			return constraints, err
+		}
+		ca.Type = normalizeConstraintType(ca.Type)
+		ca.Columns = []string{colName}
+		if cp.Name == "" {
+			cp = ca
+			continue
+		}
+		if !(ca.Owner == cp.Owner && ca.Name == cp.Name && ca.Type == cp.Type && ca.Table == cp.Table &&
+			ca.RemoteOwner == cp.RemoteOwner && ca.RemoteTable == cp.RemoteTable && ca.RemoteName == cp.RemoteName) {
+			k := cp.Owner + "." + cp.Table
+			constraints[k] = append(constraints[k], cp)
+			cp = ca
+			continue
+		}
+		cp.Columns = append(cp.Columns, colName)
+	}
+	if cp.Name != "" {
+		k := cp.Owner + "." + cp.Table
+		constraints[k] = append(constraints[k], cp)
+	}
+	return constraints, rows.Err()
+}
+
+// scanTableRowsStreaming is the streaming counterpart of scanTableRows: as
+// soon as a table's column rows are fully scanned, it is sent on out
+// rather than appended to a slice, so a caller fanning out over many
+// owner-scoped queries (see oracleReader.ReadTables) can assemble a
+// partial result even if ctx is canceled mid-crawl. prog may be nil.
+func scanTableRowsStreaming(ctx context.Context, rows *sql.Rows, out chan<- Table, prog *progress) error {
+	var tp Table
+	for rows.Next() {
+		var c Column
+		var ta Table
+		if err := rows.Scan(&ta.Owner, &ta.Name, &c.Name, &c.Type, &ta.Comment, &c.Comment); err != nil {
+			return err
+		}
+		ta.Comment = strings.TrimSpace(ta.Comment)
+		c.Comment = strings.TrimSpace(c.Comment)
+		ta.Columns = []Column{c}
+		if tp.Name == "" {
+			tp = ta
+			continue
+		}
+		if !(tp.Owner == ta.Owner && tp.Name == ta.Name) {
+			if err := sendTable(ctx, out, tp, prog); err != nil {
+				return err
+			}
+			tp = ta
+			continue
+		}
+		tp.Columns = append(tp.Columns, c)
+	}
+	if tp.Name != "" {
+		if err := sendTable(ctx, out, tp, prog); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func sendTable(ctx context.Context, out chan<- Table, t Table, prog *progress) error {
+	select {
+	case out <- t:
+		prog.addTables(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// scanConstraintRowsStreaming is the streaming counterpart of
+// scanConstraintRows: as soon as a constraint's column rows are fully
+// scanned, it is sent on out instead of appended to a map. prog may be
+// nil.
+func scanConstraintRowsStreaming(ctx context.Context, rows *sql.Rows, out chan<- Constraint, prog *progress) error {
+	var cp Constraint
+	for rows.Next() {
+		var colName string
+		var ca Constraint
+		if err := rows.Scan(
+			&ca.Owner, &ca.Name, &ca.Type, &ca.Table, &colName,
+			&ca.RemoteOwner, &ca.RemoteTable, &ca.RemoteName,
+		); err != nil {
+			return err
+		}
+		ca.Type = normalizeConstraintType(ca.Type)
+		ca.Columns = []string{colName}
+		if cp.Name == "" {
+			cp = ca
+			continue
+		}
+		if !(ca.Owner == cp.Owner && ca.Name == cp.Name && ca.Type == cp.Type && ca.Table == cp.Table &&
+			ca.RemoteOwner == cp.RemoteOwner && ca.RemoteTable == cp.RemoteTable && ca.RemoteName == cp.RemoteName) {
+			if err := sendConstraint(ctx, out, cp, prog); err != nil {
+				return err
+			}
+			cp = ca
+			continue
+		}
+		cp.Columns = append(cp.Columns, colName)
+	}
+	if cp.Name != "" {
+		if err := sendConstraint(ctx, out, cp, prog); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func sendConstraint(ctx context.Context, out chan<- Constraint, c Constraint, prog *progress) error {
+	select {
+	case out <- c:
+		prog.addConstraints(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// normalizeConstraintType maps a backend's native constraint-type spelling
+// to the Oracle-style single letters ("R" foreign key, "P" primary key,
+// "U" unique) that getTables and the writers expect.
+func normalizeConstraintType(s string) string {
+	switch s {
+	case "R", "P", "U":
+		return s
+	case "FOREIGN KEY":
+		return "R"
+	case "PRIMARY KEY":
+		return "P"
+	case "UNIQUE":
+		return "U"
+	default:
+		return s
+	}
+}
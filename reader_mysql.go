@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// mysqlReader crawls a MySQL/MariaDB catalog via information_schema, used
+// when -connect has a "mysql://" scheme.
+type mysqlReader struct {
+	db *sql.DB
+}
+
+func newMySQLReader(db *sql.DB) SchemaReader { return &mysqlReader{db: db} }
+
+func (r *mysqlReader) ReadTables(ctx context.Context, filter Filter) ([]Table, error) {
+	schemaW := schemaWhere("c.table_schema", filter.Owners)
+	qry := `
+SELECT c.table_schema, c.table_name, c.column_name,
+       c.column_type data_type,
+       COALESCE(t.table_comment, '') tab_comment,
+       COALESCE(c.column_comment, '') col_comment
+  FROM information_schema.columns c
+  JOIN information_schema.tables t
+    ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+ WHERE c.table_schema NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys') ` + schemaW + `
+ ORDER BY c.table_schema, c.table_name, c.ordinal_position`
+
+	rows, err := r.db.QueryContext(ctx, qry)
+	if err != nil {
+		return nil, errors.Wrap(err, qry)
+	}
+	defer rows.Close()
+	return scanTableRows(rows)
+}
+
+func (r *mysqlReader) ReadConstraints(ctx context.Context, filter Filter) (map[string][]Constraint, error) {
+	fkSchemaW := schemaWhere("kcu.table_schema", filter.Owners)
+	puSchemaW := schemaWhere("tc.table_schema", filter.Owners)
+	qry := `
+SELECT kcu.table_schema, kcu.constraint_name, 'FOREIGN KEY' constraint_type,
+       kcu.table_name, kcu.column_name,
+       kcu.referenced_table_schema r_owner, kcu.referenced_table_name r_table,
+       rc.unique_constraint_name r_pk
+  FROM information_schema.key_column_usage kcu
+  JOIN information_schema.referential_constraints rc
+    ON rc.constraint_schema = kcu.constraint_schema AND rc.constraint_name = kcu.constraint_name
+ WHERE kcu.referenced_table_name IS NOT NULL ` + fkSchemaW + `
+UNION ALL
+SELECT tc.table_schema, tc.constraint_name, tc.constraint_type,
+       kcu2.table_name, kcu2.column_name, NULL, NULL, NULL
+  FROM information_schema.table_constraints tc
+  JOIN information_schema.key_column_usage kcu2
+    ON kcu2.constraint_schema = tc.constraint_schema AND kcu2.constraint_name = tc.constraint_name
+ WHERE tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE') ` + puSchemaW + `
+ ORDER BY 1, 2, 3, 4, 5
+`
+
+	rows, err := r.db.QueryContext(ctx, qry)
+	if err != nil {
+		return nil, errors.Wrap(err, qry)
+	}
+	defer rows.Close()
+	return scanConstraintRows(rows)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	_ "github.com/godror/godror"
+)
+
+// oracleReader crawls an Oracle data dictionary via the all_* views. This
+// is the original (pre-SchemaReader) behavior of this tool.
+type oracleReader struct {
+	db *sql.DB
+}
+
+func newOracleReader(db *sql.DB) SchemaReader { return &oracleReader{db: db} }
+
+var replOwner = strings.NewReplacer("'", "''", "\n", " ")
+
+// ownerWhere renders filter.Owners as an "AND A.owner IN (...)" fragment,
+// or "" if filter.Owners is empty.
+func ownerWhere(owners []string) string {
+	if len(owners) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString("AND A.owner IN (")
+	for i, owner := range owners {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\'')
+		buf.WriteString(strings.ToUpper(replOwner.Replace(owner)))
+		buf.WriteByte('\'')
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// maxConcurrentOwnerQueries bounds how many owner-scoped queries
+// ReadTables and ReadConstraints run at once. Each fans out over every
+// owner independently, so without a cap a schema with thousands of
+// owners would open thousands of simultaneous connections against
+// Oracle; this keeps each fan-out to a sane connection count instead.
+const maxConcurrentOwnerQueries = 8
+
+// acquireSlot blocks until sem has room for another concurrent
+// owner-scoped query, or ctx is done first.
+func acquireSlot(ctx context.Context, sem chan struct{}) error {
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReadTables crawls all_tab_cols/all_tab_comments/all_col_comments. To
+// stay usable on schemas with tens of thousands of tables, it chunks the
+// crawl by owner (using filter.Owners, or every owner visible in
+// all_tab_cols if filter is empty) and runs up to maxConcurrentOwnerQueries
+// of those queries concurrently via errgroup.WithContext, streaming
+// completed Tables into a shared channel as soon as each one's columns
+// are fully scanned. A canceled ctx (see main's SIGINT handling) stops
+// in-flight queries and ReadTables returns whatever had already been
+// streamed, alongside ctx.Err().
+func (r *oracleReader) ReadTables(ctx context.Context, filter Filter) ([]Table, error) {
+	owners := filter.Owners
+	if len(owners) == 0 {
+		var err error
+		owners, err = r.listOwners(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prog := progressFromContext(ctx)
+	tablesCh := make(chan Table)
+	sem := make(chan struct{}, maxConcurrentOwnerQueries)
+	grp, gctx := errgroup.WithContext(ctx)
+	for _, owner := range owners {
+		owner := owner
+		grp.Go(func() error {
+			if err := acquireSlot(gctx, sem); err != nil {
+				return err
+			}
+			defer func() { <-sem }()
+			return r.streamTablesForOwner(gctx, owner, tablesCh, prog)
+		})
+	}
+	go func() {
+		grp.Wait()
+		close(tablesCh)
+	}()
+
+	var tables []Table
+	for t := range tablesCh {
+		tables = append(tables, t)
+	}
+	return tables, grp.Wait()
+}
+
+// listOwners returns every schema owner with at least one (non-$-named)
+// table, for use as the chunking key when filter.Owners is empty.
+func (r *oracleReader) listOwners(ctx context.Context) ([]string, error) {
+	const qry = `SELECT DISTINCT A.owner FROM all_tab_cols A WHERE INSTR(A.table_name, '$') = 0 ORDER BY 1`
+	rows, err := r.db.QueryContext(ctx, qry)
+	if err != nil {
+		return nil, errors.Wrap(err, qry)
+	}
+	defer rows.Close()
+	var owners []string
+	for rows.Next() {
+		var o string
+		if err := rows.Scan(&o); err != nil {
+			return owners, err
+		}
+		owners = append(owners, o)
+	}
+	return owners, rows.Err()
+}
+
+func (r *oracleReader) streamTablesForOwner(ctx context.Context, owner string, out chan<- Table, prog *progress) error {
+	ownerW := ownerWhere([]string{owner})
+	qryCols := `SELECT A.owner, A.table_name, A.column_name,
+  (CASE A.data_type
+     WHEN 'NUMBER' THEN (
+		 CASE WHEN NVL(A.data_precision, 0) = 0 THEN A.data_type
+		   WHEN NVL(A.data_scale, 0) = 0 THEN A.data_type||'('||A.data_precision||')'
+		   ELSE A.data_type||'('||A.data_precision||','||A.data_scale||')' END)
+     WHEN 'DATE' THEN A.data_type
+     ELSE A.data_type||'('||A.data_length||')'
+     END)||(CASE A.nullable WHEN 'N' THEN ' NOT NULL' END) data_type,
+     B.comments tab_comment,
+     C.comments col_comment
+  FROM all_col_comments C, all_tab_comments B, all_tab_cols A
+  WHERE C.column_name = A.column_name AND C.owner = A.owner AND C.table_name = A.table_name AND
+        B.owner = A.owner AND B.table_name = A.table_name AND INSTR(A.table_name, '$') = 0
+        ` + ownerW + `
+  ORDER BY A.owner, A.table_name, A.column_id`
+
+	rows, err := r.db.QueryContext(ctx, qryCols)
+	if err != nil {
+		return errors.Wrap(err, qryCols)
+	}
+	defer rows.Close()
+	return scanTableRowsStreaming(ctx, rows, out, prog)
+}
+
+// ReadConstraints mirrors ReadTables: it chunks the all_cons_columns/
+// all_constraints crawl by owner, bounded by maxConcurrentOwnerQueries,
+// and streams completed Constraints into a shared channel, so a canceled
+// ctx still yields a partial map.
+func (r *oracleReader) ReadConstraints(ctx context.Context, filter Filter) (map[string][]Constraint, error) {
+	owners := filter.Owners
+	if len(owners) == 0 {
+		var err error
+		owners, err = r.listOwners(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prog := progressFromContext(ctx)
+	consCh := make(chan Constraint)
+	sem := make(chan struct{}, maxConcurrentOwnerQueries)
+	grp, gctx := errgroup.WithContext(ctx)
+	for _, owner := range owners {
+		owner := owner
+		grp.Go(func() error {
+			if err := acquireSlot(gctx, sem); err != nil {
+				return err
+			}
+			defer func() { <-sem }()
+			return r.streamConstraintsForOwner(gctx, owner, consCh, prog)
+		})
+	}
+	go func() {
+		grp.Wait()
+		close(consCh)
+	}()
+
+	constraints := make(map[string][]Constraint)
+	for c := range consCh {
+		k := c.Owner + "." + c.Table
+		constraints[k] = append(constraints[k], c)
+	}
+	return constraints, grp.Wait()
+}
+
+func (r *oracleReader) streamConstraintsForOwner(ctx context.Context, owner string, out chan<- Constraint, prog *progress) error {
+	ownerW := ownerWhere([]string{owner})
+	qryCons := `
+SELECT a.owner, a.constraint_name, 'R' constraint_type,
+       a.table_name, a.column_name,
+       -- referenced pk
+       c.r_owner, c_pk.table_name r_table_name, c_pk.CONSTRAINT_NAME r_pk
+  FROM all_cons_columns a
+  JOIN all_constraints c ON a.owner = c.owner
+                        AND a.constraint_name = c.constraint_name
+  JOIN all_constraints c_pk ON c.r_owner = c_pk.owner
+                           AND c.r_constraint_name = c_pk.constraint_name
+ WHERE c.constraint_type = 'R' ` + ownerW + `
+UNION ALL
+SELECT A.owner, A.constraint_name, a.constraint_type,
+       B.table_name, B.column_name, NULL r_owner, NULL r_table_name, NULL r_pk
+  FROM all_cons_columns B, all_constraints A
+  WHERE A.constraint_type IN ('P', 'U') AND
+        B.owner = a.owner AND B.constraint_name = A.constraint_name
+        ` + ownerW + `
+  ORDER BY 1, 2, 3, 4, 5
+`
+
+	rows, err := r.db.QueryContext(ctx, qryCons)
+	if err != nil {
+		return errors.Wrap(err, qryCons)
+	}
+	defer rows.Close()
+	return scanConstraintRowsStreaming(ctx, rows, out, prog)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestScanTableRowsStreamingCompletes(t *testing.T) {
+	rows := sqlmockTableRows(t,
+		[]string{"owner", "table_name", "column_name", "data_type", "tab_comment", "col_comment"},
+		[][]driverValue{
+			{"APP", "A", "ID", "NUMBER", "", ""},
+			{"APP", "B", "ID", "NUMBER", "", ""},
+		},
+	)
+	defer rows.Close()
+
+	prog := &progress{}
+	out := make(chan Table, 2)
+	if err := scanTableRowsStreaming(context.Background(), rows, out, prog); err != nil {
+		t.Fatal(err)
+	}
+	close(out)
+
+	var got []string
+	for tbl := range out {
+		got = append(got, tbl.Name)
+	}
+	if len(got) != 2 || got[0] != "A" || got[1] != "B" {
+		t.Errorf("streamed tables = %v, want [A B]", got)
+	}
+	if prog.tables != 2 {
+		t.Errorf("prog.tables = %d, want 2", prog.tables)
+	}
+}
+
+// TestScanTableRowsStreamingCancellation exercises the partial-result
+// path a canceled crawl (see main's SIGINT handling) relies on: a
+// consumer that stops reading mid-stream should make a blocked send in
+// sendTable observe ctx.Done() and return ctx.Err(), leaving whatever
+// was already delivered (and nothing more) in the caller's hands.
+func TestScanTableRowsStreamingCancellation(t *testing.T) {
+	rows := sqlmockTableRows(t,
+		[]string{"owner", "table_name", "column_name", "data_type", "tab_comment", "col_comment"},
+		[][]driverValue{
+			{"APP", "A", "ID", "NUMBER", "", ""},
+			{"APP", "B", "ID", "NUMBER", "", ""},
+			{"APP", "C", "ID", "NUMBER", "", ""},
+		},
+	)
+	defer rows.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := make(chan Table)
+	errCh := make(chan error, 1)
+	go func() { errCh <- scanTableRowsStreaming(ctx, rows, out, nil) }()
+
+	first := <-out
+	if first.Name != "A" {
+		t.Fatalf("first streamed table = %q, want A", first.Name)
+	}
+
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("scanTableRowsStreaming error = %v, want context.Canceled", err)
+	}
+}
+
+func TestAcquireSlotBounds(t *testing.T) {
+	sem := make(chan struct{}, 1)
+	if err := acquireSlot(context.Background(), sem); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- acquireSlot(ctx, sem) }()
+
+	// The semaphore is full, so the second acquireSlot must block until
+	// canceled rather than acquiring a second concurrent slot.
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("acquireSlot on a full semaphore = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresReader crawls a PostgreSQL catalog via information_schema and
+// pg_catalog, used when -connect has a "postgres://" scheme.
+type postgresReader struct {
+	db *sql.DB
+}
+
+func newPostgresReader(db *sql.DB) SchemaReader { return &postgresReader{db: db} }
+
+// schemaWhere renders filter.Owners as an "AND alias IN (...)" fragment,
+// or "" if filter.Owners is empty.
+func schemaWhere(alias string, owners []string) string {
+	if len(owners) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString("AND ")
+	buf.WriteString(alias)
+	buf.WriteString(" IN (")
+	for i, owner := range owners {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\'')
+		buf.WriteString(strings.ReplaceAll(owner, "'", "''"))
+		buf.WriteByte('\'')
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+func (r *postgresReader) ReadTables(ctx context.Context, filter Filter) ([]Table, error) {
+	schemaW := schemaWhere("c.table_schema", filter.Owners)
+	qry := `
+SELECT c.table_schema, c.table_name, c.column_name,
+       c.data_type ||
+         CASE WHEN c.character_maximum_length IS NOT NULL THEN '('||c.character_maximum_length||')'
+              WHEN c.numeric_precision IS NOT NULL AND COALESCE(c.numeric_scale, 0) > 0
+                THEN '('||c.numeric_precision||','||c.numeric_scale||')'
+              WHEN c.numeric_precision IS NOT NULL THEN '('||c.numeric_precision||')'
+              ELSE '' END ||
+         CASE WHEN c.is_nullable = 'NO' THEN ' NOT NULL' ELSE '' END data_type,
+       COALESCE(pgd.description, '') tab_comment,
+       COALESCE(pgcd.description, '') col_comment
+  FROM information_schema.columns c
+  JOIN pg_catalog.pg_statio_all_tables st ON st.schemaname = c.table_schema AND st.relname = c.table_name
+  LEFT JOIN pg_catalog.pg_description pgd ON pgd.objoid = st.relid AND pgd.objsubid = 0
+  LEFT JOIN pg_catalog.pg_description pgcd ON pgcd.objoid = st.relid AND pgcd.objsubid = c.ordinal_position
+ WHERE c.table_schema NOT IN ('pg_catalog', 'information_schema') ` + schemaW + `
+ ORDER BY c.table_schema, c.table_name, c.ordinal_position`
+
+	rows, err := r.db.QueryContext(ctx, qry)
+	if err != nil {
+		return nil, errors.Wrap(err, qry)
+	}
+	defer rows.Close()
+	return scanTableRows(rows)
+}
+
+func (r *postgresReader) ReadConstraints(ctx context.Context, filter Filter) (map[string][]Constraint, error) {
+	fkSchemaW := schemaWhere("tc.table_schema", filter.Owners)
+	puSchemaW := schemaWhere("tc2.table_schema", filter.Owners)
+	qry := `
+SELECT tc.table_schema, tc.constraint_name, 'FOREIGN KEY' constraint_type,
+       tc.table_name, kcu.column_name,
+       ccu.table_schema r_owner, ccu.table_name r_table, rc.unique_constraint_name r_pk
+  FROM information_schema.table_constraints tc
+  JOIN information_schema.key_column_usage kcu
+    ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+  JOIN information_schema.referential_constraints rc
+    ON rc.constraint_schema = tc.constraint_schema AND rc.constraint_name = tc.constraint_name
+  JOIN information_schema.constraint_column_usage ccu
+    ON ccu.constraint_schema = rc.unique_constraint_schema AND ccu.constraint_name = rc.unique_constraint_name
+ WHERE tc.constraint_type = 'FOREIGN KEY' ` + fkSchemaW + `
+UNION ALL
+SELECT tc2.table_schema, tc2.constraint_name, tc2.constraint_type,
+       kcu2.table_name, kcu2.column_name, NULL, NULL, NULL
+  FROM information_schema.table_constraints tc2
+  JOIN information_schema.key_column_usage kcu2
+    ON kcu2.constraint_schema = tc2.constraint_schema AND kcu2.constraint_name = tc2.constraint_name
+ WHERE tc2.constraint_type IN ('PRIMARY KEY', 'UNIQUE') ` + puSchemaW + `
+ ORDER BY 1, 2, 3, 4, 5
+`
+
+	rows, err := r.db.QueryContext(ctx, qry)
+	if err != nil {
+		return nil, errors.Wrap(err, qry)
+	}
+	defer rows.Close()
+	return scanConstraintRows(rows)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitScheme(t *testing.T) {
+	cases := []struct {
+		connect    string
+		wantScheme string
+		wantDSN    string
+	}{
+		{"user/pw@tns", "", "user/pw@tns"},
+		{"", "", ""},
+		{"oracle://user/pw@tns", "oracle", "user/pw@tns"},
+		{"postgres://user:pw@host:5432/db?sslmode=disable", "postgres", "user:pw@host:5432/db?sslmode=disable"},
+		{"mysql://user:pw@tcp(host:3306)/db", "mysql", "user:pw@tcp(host:3306)/db"},
+	}
+	for _, c := range cases {
+		scheme, dsn := splitScheme(c.connect)
+		if scheme != c.wantScheme || dsn != c.wantDSN {
+			t.Errorf("splitScheme(%q) = (%q, %q), want (%q, %q)", c.connect, scheme, dsn, c.wantScheme, c.wantDSN)
+		}
+	}
+}
+
+func TestDSNFor(t *testing.T) {
+	cases := []struct{ scheme, dsn, want string }{
+		{"postgres", "user:pw@host:5432/db", "postgres://user:pw@host:5432/db"},
+		{"mysql", "user:pw@tcp(host:3306)/db", "user:pw@tcp(host:3306)/db"},
+		{"oracle", "user/pw@tns", "user/pw@tns"},
+		{"", "user/pw@tns", "user/pw@tns"},
+	}
+	for _, c := range cases {
+		if got := dsnFor(c.scheme, c.dsn); got != c.want {
+			t.Errorf("dsnFor(%q, %q) = %q, want %q", c.scheme, c.dsn, got, c.want)
+		}
+	}
+}
+
+func TestOpenReaderDispatch(t *testing.T) {
+	cases := []struct {
+		connect  string
+		wantType SchemaReader
+	}{
+		{"user/pw@tns", &oracleReader{}},
+		{"oracle://user/pw@tns", &oracleReader{}},
+		{"postgres://user:pw@host/db", &postgresReader{}},
+		{"mysql://user:pw@tcp(host)/db", &mysqlReader{}},
+	}
+	for _, c := range cases {
+		reader, db, err := openReader(c.connect)
+		if err != nil {
+			t.Fatalf("openReader(%q): %+v", c.connect, err)
+		}
+		db.Close()
+		if got, want := reflect.TypeOf(reader), reflect.TypeOf(c.wantType); got != want {
+			t.Errorf("openReader(%q) returned %v, want %v", c.connect, got, want)
+		}
+	}
+}
+
+func TestOpenReaderUnknownScheme(t *testing.T) {
+	_, _, err := openReader("sqlite://foo.db")
+	if err == nil || !strings.Contains(err.Error(), "unknown scheme") {
+		t.Errorf("openReader with unknown scheme: got err %v, want one mentioning \"unknown scheme\"", err)
+	}
+}
+
+func TestOwnerWhere(t *testing.T) {
+	if got := ownerWhere(nil); got != "" {
+		t.Errorf("ownerWhere(nil) = %q, want empty", got)
+	}
+	if got, want := ownerWhere([]string{"app"}), "AND A.owner IN ('APP')"; got != want {
+		t.Errorf("ownerWhere = %q, want %q", got, want)
+	}
+	if got, want := ownerWhere([]string{"app", "o'hare"}), "AND A.owner IN ('APP','O''HARE')"; got != want {
+		t.Errorf("ownerWhere did not escape quote: got %q, want %q", got, want)
+	}
+}
+
+func TestSchemaWhere(t *testing.T) {
+	if got := schemaWhere("c.table_schema", nil); got != "" {
+		t.Errorf("schemaWhere(nil) = %q, want empty", got)
+	}
+	if got, want := schemaWhere("c.table_schema", []string{"app"}), "AND c.table_schema IN ('app')"; got != want {
+		t.Errorf("schemaWhere = %q, want %q", got, want)
+	}
+	if got, want := schemaWhere("c.table_schema", []string{"o'hare"}), "AND c.table_schema IN ('o''hare')"; got != want {
+		t.Errorf("schemaWhere did not escape quote: got %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeConstraintType(t *testing.T) {
+	cases := map[string]string{
+		"R":           "R",
+		"P":           "P",
+		"U":           "U",
+		"FOREIGN KEY": "R",
+		"PRIMARY KEY": "P",
+		"UNIQUE":      "U",
+		"CHECK":       "CHECK",
+	}
+	for in, want := range cases {
+		if got := normalizeConstraintType(in); got != want {
+			t.Errorf("normalizeConstraintType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestScanTableRows(t *testing.T) {
+	rows := sqlmockTableRows(t,
+		[]string{"owner", "table_name", "column_name", "data_type", "tab_comment", "col_comment"},
+		[][]driverValue{
+			{"APP", "USERS", "ID", "NUMBER", "application users", "primary key"},
+			{"APP", "USERS", "NAME", "VARCHAR2(100)", "application users", ""},
+			{"APP", "ORDERS", "ID", "NUMBER", "", ""},
+		},
+	)
+	defer rows.Close()
+
+	tables, err := scanTableRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("len(tables) = %d, want 2", len(tables))
+	}
+	if tables[0].Name != "USERS" || len(tables[0].Columns) != 2 {
+		t.Errorf("tables[0] = %+v, want USERS with 2 columns", tables[0])
+	}
+	if tables[1].Name != "ORDERS" || len(tables[1].Columns) != 1 {
+		t.Errorf("tables[1] = %+v, want ORDERS with 1 column", tables[1])
+	}
+}
+
+func TestScanConstraintRows(t *testing.T) {
+	rows := sqlmockTableRows(t,
+		[]string{"owner", "name", "type", "table", "col", "r_owner", "r_table", "r_name"},
+		[][]driverValue{
+			{"APP", "USERS_PK", "P", "USERS", "ID", "", "", ""},
+			{"APP", "ORDERS_FK", "R", "ORDERS", "USER_ID", "APP", "USERS", "USERS_PK"},
+			{"APP", "ORDERS_FK", "R", "ORDERS", "EXTRA_ID", "APP", "USERS", "USERS_PK"},
+		},
+	)
+	defer rows.Close()
+
+	constraints, err := scanConstraintRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := constraints["APP.USERS"]; len(got) != 1 || got[0].Type != "P" {
+		t.Errorf("constraints[APP.USERS] = %+v, want one P constraint", got)
+	}
+	if got := constraints["APP.ORDERS"]; len(got) != 1 || len(got[0].Columns) != 2 {
+		t.Errorf("constraints[APP.ORDERS] = %+v, want one R constraint spanning 2 columns", got)
+	}
+}
+
+// driverValue is a value accepted by sqlmockTableRows's driver.Value rows.
+type driverValue interface{}
+
+// sqlmockTableRows builds a *sql.Rows backed by an in-memory fake
+// database/sql/driver, so scanTableRows/scanConstraintRows can be unit
+// tested without a live database connection.
+func sqlmockTableRows(t *testing.T, cols []string, data [][]driverValue) *sql.Rows {
+	t.Helper()
+	db := sql.OpenDB(fakeConnector{cols: cols, data: data})
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}